@@ -0,0 +1,49 @@
+package firebase
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// DatabaseScope is the OAuth2 scope required to read and write the
+// Firebase Realtime Database. Firebase deprecated the database secret in
+// favor of Google OAuth2 access tokens carrying this scope (plus
+// userinfo.email, which Firebase uses to resolve auth.token.email in
+// security rules).
+const DatabaseScope = "https://www.googleapis.com/auth/firebase.database"
+
+// EmailScope lets Firebase resolve auth.token.email in security rules for
+// the authenticated service account.
+const EmailScope = "https://www.googleapis.com/auth/userinfo.email"
+
+// NewDBClientWithTokenSource retrieves a new Firebase client authenticated
+// with a Google OAuth2 access token instead of the legacy database secret.
+// The token is sent as "Authorization: Bearer <token>" on every request and
+// refreshed automatically via ts when it expires.
+func NewDBClientWithTokenSource(baseUrl string, ts oauth2.TokenSource, export bool, client IRequestClient) *dbClient {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &dbClient{
+		baseUrl:     baseUrl,
+		tokenSource: ts,
+		export:      export,
+		client:      client,
+	}
+}
+
+// GoogleServiceAccountTokenSource loads a service-account JSON key (as
+// downloaded from the Google Cloud console), signs a JWT with it and
+// exchanges that JWT for an OAuth2 access token scoped to the Firebase
+// database and the caller's email. The returned TokenSource refreshes
+// itself as tokens expire.
+func GoogleServiceAccountTokenSource(ctx context.Context, jsonKey []byte) (oauth2.TokenSource, error) {
+	cfg, err := google.JWTConfigFromJSON(jsonKey, DatabaseScope, EmailScope)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.TokenSource(ctx), nil
+}
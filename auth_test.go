@@ -0,0 +1,54 @@
+package firebase
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestNewDBClientWithTokenSourceSetsBearerHeader(t *testing.T) {
+	var gotAuth string
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		gotAuth = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "test-token"})
+	c := NewDBClientWithTokenSource("https://example.firebaseio.com", ts, false, client)
+
+	var v map[string]int
+	if err := c.Get("/a", &v); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if want := "Bearer test-token"; gotAuth != want {
+		t.Errorf("Authorization = %q, want %q", gotAuth, want)
+	}
+}
+
+func TestTokenSourceTakesPrecedenceOverStaleAccessToken(t *testing.T) {
+	var gotAuth string
+	var gotQuery string
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		gotAuth = req.Header.Get("Authorization")
+		gotQuery = req.URL.Query().Get(ParamAccessToken)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "fresh-token"})
+	c := NewDBClientWithTokenSource("https://example.firebaseio.com", ts, false, client)
+	// Simulate a client that also carries a stale legacy secret; the
+	// tokenSource must win and the secret must not leak into the query string.
+	c.accessToken = "stale-secret"
+
+	var v map[string]int
+	if err := c.Get("/a", &v); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if want := "Bearer fresh-token"; gotAuth != want {
+		t.Errorf("Authorization = %q, want %q", gotAuth, want)
+	}
+	if gotQuery != "" {
+		t.Errorf("expected no %s query param once a tokenSource is set, got %q", ParamAccessToken, gotQuery)
+	}
+}
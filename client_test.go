@@ -0,0 +1,14 @@
+package firebase
+
+import "net/http"
+
+// fakeClient is a minimal IRequestClient stand-in for tests that need to
+// inspect outgoing requests or script canned responses without touching
+// the network.
+type fakeClient struct {
+	do func(req *http.Request) (*http.Response, error)
+}
+
+func (f *fakeClient) Do(req *http.Request) (*http.Response, error) {
+	return f.do(req)
+}
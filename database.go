@@ -2,13 +2,15 @@ package firebase
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
-	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
 )
 
 type Method string
@@ -17,7 +19,7 @@ const (
 	POST   Method = "POST"
 	GET    Method = "GET"
 	PATCH  Method = "PATCH"
-	DELETE Method = "DELETET"
+	DELETE Method = "DELETE"
 	PUT    Method = "PUT"
 )
 
@@ -38,17 +40,38 @@ const (
 	//indicated by a 204 No Content HTTP status code.
 	ParamPrint    = "print"
 	ParamDownload = "download"
-	paramOrderBy  = "orderBy" //todo implement
+
+	//ParamOrderBy - orders the results, by the value of a named child node, by a special $key or
+	//$value location, or by priority. Required when using any of StartAt, EndAt, EqualTo,
+	//LimitToFirst or LimitToLast. Use the Ref query builder rather than this constant directly.
+	ParamOrderBy      = "orderBy"
+	ParamStartAt      = "startAt"
+	ParamEndAt        = "endAt"
+	ParamEqualTo      = "equalTo"
+	ParamLimitToFirst = "limitToFirst"
+	ParamLimitToLast  = "limitToLast"
 )
 
 var availableParams = map[Method][]string{
 	POST:   {ParamAccessToken, ParamPrint},
-	GET:    {ParamAccessToken, ParamShallow, ParamPrint},
+	GET:    {ParamAccessToken, ParamShallow, ParamPrint, ParamOrderBy, ParamStartAt, ParamEndAt, ParamEqualTo, ParamLimitToFirst, ParamLimitToLast},
 	PATCH:  {ParamAccessToken, ParamPrint},
 	DELETE: {ParamAccessToken, ParamPrint},
 	PUT:    {ParamAccessToken, ParamPrint},
 }
 
+// paramAllowed reports whether param is usable as a query param for method,
+// e.g. the orderBy/limitTo*/startAt/endAt/equalTo family only make sense on
+// a GET: Firebase has no concept of an ordered write.
+func paramAllowed(method Method, param string) bool {
+	for _, p := range availableParams[method] {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
+
 // IRequestClient client interface
 type IRequestClient interface {
 	Do(req *http.Request) (resp *http.Response, err error)
@@ -58,11 +81,18 @@ type dbClient struct {
 	baseUrl     string
 	client      IRequestClient
 	accessToken string
-	export      bool //If set to export, the server will encode priorities in the response.
-	shallow     bool //Limit the depth of the response
+	tokenSource oauth2.TokenSource //OAuth2 access token, takes precedence over accessToken when set.
+	export      bool               //If set to export, the server will encode priorities in the response.
+	shallow     bool               //Limit the depth of the response
+	retryPolicy *RetryPolicy       //If set, via SetRetryPolicy, transient failures are retried.
+	logger      Logger             //If set, via SetLogger/SetDebug, every request/response is dumped to it.
 }
 
-// Retrieve a new Firebase Client
+// NewDBClient retrieves a new Firebase client authenticated with the
+// legacy database secret / auth token, sent as the "auth" query param.
+//
+// Deprecated: Firebase has deprecated database secrets in favor of Google
+// OAuth2 access tokens; use NewDBClientWithTokenSource instead.
 // baseUrl, accessToken - required
 func NewDBClient(baseUrl, accessToken string, export bool, client IRequestClient) *dbClient {
 	if client == nil {
@@ -76,43 +106,75 @@ func NewDBClient(baseUrl, accessToken string, export bool, client IRequestClient
 	}
 }
 
-// Execute a new HTTP Request.
-func (c *dbClient) executeRequest(method Method, path string, body []byte) ([]byte, error) {
-
-	req, err := c.buildRequest(path, method, body)
-	if err != nil {
-		return nil, err
-	}
-	// Make actual HTTP request.
-	res, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
+// executeRequestContext executes a new HTTP request with extra query
+// params (e.g. the orderBy/startAt/.../limitToLast family built by Ref)
+// against a caller-supplied context, used by GetContext, WriteContext,
+// CreateContext, UpdateContext, DeleteContext and Ref.GetContext for
+// cancellation and deadlines. The ETag variants in etag.go go through
+// buildRequest and doRaw instead.
+func (c *dbClient) executeRequestContext(ctx context.Context, method Method, path string, body []byte, extra url.Values) ([]byte, error) {
+	maxAttempts := 1
+	var policy RetryPolicy
+	if c.retryPolicy != nil && c.retryPolicy.retryable(method) {
+		policy = *c.retryPolicy
+		maxAttempts = policy.MaxAttempts
 	}
 
-	defer res.Body.Close()
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(policy.backoff(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
 
-	if h := res.Header.Get(debugHeader); h != "" {
-		log.Printf("Debug: %s", h)
-	}
-	// Check status code for errors.
-	status := res.Status
-	if strings.HasPrefix(status, "2") == false {
-		return nil, errors.New(status)
-	}
+		// buildRequest takes body as a []byte and wraps it in a fresh
+		// bytes.Reader on every call, so each attempt gets an unconsumed
+		// body for free.
+		req, err := c.buildRequest(ctx, path, method, body, extra)
+		if err != nil {
+			return nil, err
+		}
 
-	// Read body.
-	resBody, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+		// Make actual HTTP request.
+		res, err := c.doWithDebug(req, true)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		resBody, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check status code for errors. Firebase always returns a JSON
+		// error body (e.g. a missing .indexOn rule on a 400); NewError
+		// decodes it and attaches any X-Firebase-Auth-Debug header rather
+		// than just logging.
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			lastErr = NewError(res, resBody)
+			if attempt < maxAttempts-1 && retryableStatus(res.StatusCode) {
+				continue
+			}
+			return nil, lastErr
+		}
+
+		return resBody, nil
 	}
 
-	return resBody, nil
+	return nil, lastErr
 }
 
-func (c *dbClient) buildRequest(path string, method Method, body []byte) (*http.Request, error) {
+func (c *dbClient) buildRequest(ctx context.Context, path string, method Method, body []byte, extra url.Values) (*http.Request, error) {
 	//Build query params
 	q := url.Values{}
-	if c.accessToken != "" {
+	if c.tokenSource == nil && c.accessToken != "" {
 		q.Add(ParamAccessToken, c.accessToken)
 	}
 	if c.export {
@@ -121,10 +183,31 @@ func (c *dbClient) buildRequest(path string, method Method, body []byte) (*http.
 	if c.shallow {
 		q.Add(ParamShallow, "true")
 	}
+	for k, vs := range extra {
+		if !paramAllowed(method, k) {
+			return nil, fmt.Errorf("firebase: %s is not a valid query param for %s", k, method)
+		}
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
 	// Prepare HTTP Request
 	u := c.baseUrl + path + ".json" + "?" + q.Encode()
 
-	return http.NewRequest(string(method), u, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, string(method), u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token()
+		if err != nil {
+			return nil, fmt.Errorf("firebase: fetching oauth2 token: %w", err)
+		}
+		token.SetAuthHeader(req)
+	}
+
+	return req, nil
 }
 
 // Get the current value for this Reference.
@@ -132,7 +215,13 @@ func (c *dbClient) buildRequest(path string, method Method, body []byte) (*http.
 // A successful request will be indicated by a 200 OK HTTP status code.
 // The response will contain the data being retrieved
 func (c *dbClient) Get(path string, v interface{}) error {
-	resp, err := c.executeRequest(GET, path, nil)
+	return c.GetContext(context.Background(), path, v)
+}
+
+// GetContext is Get with a caller-supplied context, for cancellation,
+// deadlines or tracing.
+func (c *dbClient) GetContext(ctx context.Context, path string, v interface{}) error {
+	resp, err := c.executeRequestContext(ctx, GET, path, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -145,11 +234,16 @@ func (c *dbClient) Get(path string, v interface{}) error {
 // Write the value for this Reference (overwrites existing value).
 // A successful request will be indicated by a 200 OK HTTP status code.
 func (c *dbClient) Write(path string, v interface{}) error {
+	return c.WriteContext(context.Background(), path, v)
+}
+
+// WriteContext is Write with a caller-supplied context.
+func (c *dbClient) WriteContext(ctx context.Context, path string, v interface{}) error {
 	jsonData, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
-	_, err = c.executeRequest(PUT, path, jsonData)
+	_, err = c.executeRequestContext(ctx, PUT, path, jsonData, nil)
 	if err != nil {
 		return err
 	}
@@ -160,11 +254,16 @@ func (c *dbClient) Write(path string, v interface{}) error {
 // Create a new object to this Reference (effectively creates a list).
 // A successful request will be indicated by a 200 OK HTTP status code.
 func (c *dbClient) Create(path string, v interface{}) error {
+	return c.CreateContext(context.Background(), path, v)
+}
+
+// CreateContext is Create with a caller-supplied context.
+func (c *dbClient) CreateContext(ctx context.Context, path string, v interface{}) error {
 	jsonData, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
-	if _, err = c.executeRequest(POST, path, jsonData); err != nil {
+	if _, err = c.executeRequestContext(ctx, POST, path, jsonData, nil); err != nil {
 		return err
 	}
 
@@ -173,11 +272,16 @@ func (c *dbClient) Create(path string, v interface{}) error {
 
 // Update node with give data
 func (c *dbClient) Update(path string, v interface{}) error {
+	return c.UpdateContext(context.Background(), path, v)
+}
+
+// UpdateContext is Update with a caller-supplied context.
+func (c *dbClient) UpdateContext(ctx context.Context, path string, v interface{}) error {
 	jsonData, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
-	if _, err = c.executeRequest(PATCH, path, jsonData); err != nil {
+	if _, err = c.executeRequestContext(ctx, PATCH, path, jsonData, nil); err != nil {
 		return err
 	}
 
@@ -186,6 +290,11 @@ func (c *dbClient) Update(path string, v interface{}) error {
 
 // Delete any values for this node
 func (c *dbClient) Delete(path string) error {
-	_, err := c.executeRequest(DELETE, path, nil)
+	return c.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext is Delete with a caller-supplied context.
+func (c *dbClient) DeleteContext(ctx context.Context, path string) error {
+	_, err := c.executeRequestContext(ctx, DELETE, path, nil, nil)
 	return err
 }
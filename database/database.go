@@ -2,12 +2,13 @@ package database
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"strings"
+
+	firebase "github.com/ereminIvan/firebase"
 )
 
 type client interface {
@@ -56,6 +57,12 @@ func (c *DBClient) Export(toggle bool) *DBClient {
 
 // Execute a new HTTP Request.
 func (c *DBClient) executeRequest(method string, body []byte) ([]byte, error) {
+	return c.executeRequestContext(context.Background(), method, body)
+}
+
+// executeRequestContext is executeRequest with an explicit context, used by
+// every *Context method variant for cancellation and deadlines.
+func (c *DBClient) executeRequestContext(ctx context.Context, method string, body []byte) ([]byte, error) {
 	url, err := url.Parse(c.url + c.postfix)
 	if err != nil {
 		return nil, err
@@ -63,12 +70,12 @@ func (c *DBClient) executeRequest(method string, body []byte) ([]byte, error) {
 	if c.secret != "" {
 		url.Query().Set("auth", c.secret)
 	}
-	if c.export != "" {
+	if c.export {
 		url.Query().Set("format", "export")
 	}
 
 	// Prepare HTTP Request
-	req, err := http.NewRequest(method, url.String(), bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, method, url.String(), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -80,25 +87,31 @@ func (c *DBClient) executeRequest(method string, body []byte) ([]byte, error) {
 
 	defer c.response.Body.Close()
 
-	// Check status code for errors.
-	status := c.response.Status
-	if strings.HasPrefix(status, "2") == false {
-		return nil, errors.New(status)
-	}
-
 	// Read body.
 	if c.responseBody, err = ioutil.ReadAll(c.response.Body); err != nil {
 		return nil, err
 	}
 
+	// Check status code for errors. Firebase always returns a JSON error
+	// body of the form {"error":"..."}; firebase.NewError decodes it and
+	// attaches any X-Firebase-Auth-Debug header rather than discarding both.
+	if c.response.StatusCode < 200 || c.response.StatusCode >= 300 {
+		return nil, firebase.NewError(c.response, c.responseBody)
+	}
+
 	return c.responseBody, nil
 }
 
 // Retrieve the current value for this Reference.
 func (c *DBClient) Value(v interface{}) error {
+	return c.ValueContext(context.Background(), v)
+}
+
+// ValueContext is Value with a caller-supplied context.
+func (c *DBClient) ValueContext(ctx context.Context, v interface{}) error {
 
 	// GET the data from Firebase.
-	resp, err := c.executeRequest("GET", nil)
+	resp, err := c.executeRequestContext(ctx, "GET", nil)
 	if err != nil {
 		return err
 	}
@@ -114,6 +127,11 @@ func (c *DBClient) Value(v interface{}) error {
 
 // Set the value for this Reference (overwrites existing value).
 func (c *DBClient) Write(v interface{}) error {
+	return c.WriteContext(context.Background(), v)
+}
+
+// WriteContext is Write with a caller-supplied context.
+func (c *DBClient) WriteContext(ctx context.Context, v interface{}) error {
 
 	// JSON encode the data.
 	jsonData, err := json.Marshal(v)
@@ -122,7 +140,7 @@ func (c *DBClient) Write(v interface{}) error {
 	}
 
 	// PUT the data to Firebase.
-	_, err = c.executeRequest("PUT", jsonData)
+	_, err = c.executeRequestContext(ctx, "PUT", jsonData)
 	if err != nil {
 		return err
 	}
@@ -132,6 +150,11 @@ func (c *DBClient) Write(v interface{}) error {
 
 // Pushes a new object to this Reference (effectively creates a list).
 func (c *DBClient) Push(v interface{}) error {
+	return c.PushContext(context.Background(), v)
+}
+
+// PushContext is Push with a caller-supplied context.
+func (c *DBClient) PushContext(ctx context.Context, v interface{}) error {
 
 	// JSON encode the data.
 	jsonData, err := json.Marshal(v)
@@ -140,7 +163,7 @@ func (c *DBClient) Push(v interface{}) error {
 	}
 
 	// POST the data to Firebase.
-	_, err = c.executeRequest("POST", jsonData)
+	_, err = c.executeRequestContext(ctx, "POST", jsonData)
 	if err != nil {
 		return err
 	}
@@ -150,6 +173,11 @@ func (c *DBClient) Push(v interface{}) error {
 
 // Update node with give data
 func (c *DBClient) Update(v interface{}) error {
+	return c.UpdateContext(context.Background(), v)
+}
+
+// UpdateContext is Update with a caller-supplied context.
+func (c *DBClient) UpdateContext(ctx context.Context, v interface{}) error {
 
 	// JSON encode the data.
 	jsonData, err := json.Marshal(v)
@@ -158,7 +186,7 @@ func (c *DBClient) Update(v interface{}) error {
 	}
 
 	// PATCH the data on Firebase.
-	_, err = c.executeRequest("PATCH", jsonData)
+	_, err = c.executeRequestContext(ctx, "PATCH", jsonData)
 	if err != nil {
 		return err
 	}
@@ -168,7 +196,12 @@ func (c *DBClient) Update(v interface{}) error {
 
 // Delete any values for this node
 func (c *DBClient) Delete() error {
-	_, err := c.executeRequest("DELETE", nil)
+	return c.DeleteContext(context.Background())
+}
+
+// DeleteContext is Delete with a caller-supplied context.
+func (c *DBClient) DeleteContext(ctx context.Context) error {
+	_, err := c.executeRequestContext(ctx, "DELETE", nil)
 	if err != nil {
 		return err
 	}
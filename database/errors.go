@@ -0,0 +1,21 @@
+package database
+
+import (
+	firebase "github.com/ereminIvan/firebase"
+)
+
+// Error is the same typed error firebase.dbClient returns for a non-2xx
+// response, reused here rather than duplicated so both clients share one
+// implementation of Firebase's error semantics.
+type Error = firebase.Error
+
+// IsPermissionDenied reports whether err is a Firebase security-rule
+// rejection.
+var IsPermissionDenied = firebase.IsPermissionDenied
+
+// IsNotFound reports whether err came back as HTTP 404.
+var IsNotFound = firebase.IsNotFound
+
+// IsUnauthorized reports whether err came back as HTTP 401, e.g. an
+// expired or invalid auth token.
+var IsUnauthorized = firebase.IsUnauthorized
@@ -0,0 +1,85 @@
+package firebase
+
+import (
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"time"
+)
+
+// Logger is the minimal interface dbClient needs for wire-level debug
+// logging, so callers can route it through zap/logrus/etc instead of the
+// standard log package.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+}
+
+// stdLogger adapts the standard log package to Logger; it's what SetDebug
+// installs.
+type stdLogger struct{}
+
+func (stdLogger) Debugf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+// SetLogger enables wire-level debug logging through logger: every request
+// and response is dumped (via httputil.DumpRequestOut / DumpResponse; bodies
+// only up to maxDebugDumpBody, see doWithDebug) along with elapsed time and
+// the final URL, which speeds up
+// debugging security-rule and query-param issues, the most common Firebase
+// REST failures. Pass nil to disable logging again.
+func (c *dbClient) SetLogger(logger Logger) {
+	c.logger = logger
+}
+
+// SetDebug is shorthand for SetLogger(stdLogger{}) / SetLogger(nil), for
+// the common case of just wanting the dump on stderr via log.
+func (c *dbClient) SetDebug(debug bool) {
+	if debug {
+		c.logger = stdLogger{}
+		return
+	}
+	c.logger = nil
+}
+
+// maxDebugDumpBody caps how large a request/response body SetDebug/
+// SetLogger will dump in full; beyond this (or for streaming endpoints,
+// where dumpBody is false) only headers are logged, so a multi-megabyte
+// Write or a live SSE connection doesn't flood the log.
+const maxDebugDumpBody = 64 * 1024
+
+// doWithDebug runs req through c.client.Do, dumping the request/response
+// to c.logger when debug logging is enabled. dumpBody should be false for
+// streaming endpoints (SSE), where the body is never-ending; very large
+// JSON payloads are elided automatically via maxDebugDumpBody regardless of
+// dumpBody.
+func (c *dbClient) doWithDebug(req *http.Request, dumpBody bool) (*http.Response, error) {
+	if c.logger == nil {
+		return c.client.Do(req)
+	}
+
+	if dump, err := httputil.DumpRequestOut(req, dumpBody && withinDebugDumpLimit(req.ContentLength)); err == nil {
+		c.logger.Debugf("firebase: request:\n%s", dump)
+	}
+
+	start := time.Now()
+	res, err := c.client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		c.logger.Debugf("firebase: %s %s failed after %s: %v", req.Method, req.URL, elapsed, err)
+		return res, err
+	}
+
+	if dump, err := httputil.DumpResponse(res, dumpBody && withinDebugDumpLimit(res.ContentLength)); err == nil {
+		c.logger.Debugf("firebase: %s %s (%s):\n%s", req.Method, req.URL, elapsed, dump)
+	}
+
+	return res, nil
+}
+
+// withinDebugDumpLimit reports whether a body of contentLength is small
+// enough to dump in full. -1 (unknown/chunked length) is treated as too
+// large, since we can't bound it up front.
+func withinDebugDumpLimit(contentLength int64) bool {
+	return contentLength >= 0 && contentLength <= maxDebugDumpBody
+}
@@ -0,0 +1,100 @@
+package firebase
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fakeLogger is a minimal Logger stand-in that records every Debugf call so
+// tests can assert on what was (or wasn't) dumped.
+type fakeLogger struct {
+	calls []string
+}
+
+func (f *fakeLogger) Debugf(format string, args ...interface{}) {
+	f.calls = append(f.calls, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeLogger) dumped() string {
+	return strings.Join(f.calls, "\n")
+}
+
+func newDebugTestClient(body string) (*dbClient, *fakeLogger) {
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+		}, nil
+	}}
+	logger := &fakeLogger{}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+	c.SetLogger(logger)
+	return c, logger
+}
+
+func TestDoWithDebugIncludesBodyWithinLimit(t *testing.T) {
+	c, logger := newDebugTestClient(`{"ok":true}`)
+	req, err := http.NewRequest("GET", "https://example.firebaseio.com/a.json", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	if _, err := c.doWithDebug(req, true); err != nil {
+		t.Fatalf("doWithDebug: %v", err)
+	}
+
+	if !strings.Contains(logger.dumped(), `{"ok":true}`) {
+		t.Errorf("expected the response body to be dumped, got: %s", logger.dumped())
+	}
+}
+
+func TestDoWithDebugElidesBodyAboveLimit(t *testing.T) {
+	big := strings.Repeat("x", maxDebugDumpBody+1)
+	c, logger := newDebugTestClient(big)
+	req, err := http.NewRequest("GET", "https://example.firebaseio.com/a.json", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.ContentLength = -1 // unknown request length; the response's known length is over the limit
+
+	if _, err := c.doWithDebug(req, true); err != nil {
+		t.Fatalf("doWithDebug: %v", err)
+	}
+
+	if strings.Contains(logger.dumped(), big) {
+		t.Errorf("expected the oversized body to be elided, but it was dumped in full")
+	}
+}
+
+func TestDoWithDebugNeverDumpsBodyWhenDumpBodyFalse(t *testing.T) {
+	c, logger := newDebugTestClient(`{"ok":true}`)
+	req, err := http.NewRequest("GET", "https://example.firebaseio.com/a.json", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	if _, err := c.doWithDebug(req, false); err != nil {
+		t.Fatalf("doWithDebug: %v", err)
+	}
+
+	if strings.Contains(logger.dumped(), `{"ok":true}`) {
+		t.Errorf("expected the body to never be dumped when dumpBody=false, got: %s", logger.dumped())
+	}
+}
+
+func TestWithinDebugDumpLimit(t *testing.T) {
+	if !withinDebugDumpLimit(maxDebugDumpBody) {
+		t.Errorf("a body exactly at the limit should be within it")
+	}
+	if withinDebugDumpLimit(maxDebugDumpBody + 1) {
+		t.Errorf("a body over the limit should not be within it")
+	}
+	if withinDebugDumpLimit(-1) {
+		t.Errorf("an unknown (-1) content length should be treated as too large")
+	}
+}
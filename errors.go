@@ -0,0 +1,72 @@
+package firebase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is returned whenever a request receives a non-2xx response. It
+// carries the decoded Firebase error body (and any X-Firebase-Auth-Debug
+// header) so callers can branch on Firebase's semantics instead of
+// string-matching err.Error().
+type Error struct {
+	StatusCode int
+	Status     string
+	Message    string
+	Body       []byte
+	//AuthDebug holds the contents of the X-Firebase-Auth-Debug header, which
+	//Firebase sets to explain why a security rule rejected the request.
+	AuthDebug string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("firebase: %s: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("firebase: %s", e.Status)
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// NewError builds an *Error from a non-2xx response and its already-read
+// body. It's exported so the legacy database package can reuse it rather
+// than re-implementing error parsing.
+func NewError(res *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Body:       body,
+		AuthDebug:  res.Header.Get(debugHeader),
+	}
+	if strings.Contains(res.Header.Get("Content-Type"), "json") {
+		var b errorBody
+		if err := json.Unmarshal(body, &b); err == nil {
+			e.Message = b.Error
+		}
+	}
+	return e
+}
+
+// IsPermissionDenied reports whether err is a Firebase security-rule
+// rejection.
+func IsPermissionDenied(err error) bool {
+	fe, ok := err.(*Error)
+	return ok && strings.Contains(strings.ToLower(fe.Message), "permission denied")
+}
+
+// IsNotFound reports whether err came back as HTTP 404.
+func IsNotFound(err error) bool {
+	fe, ok := err.(*Error)
+	return ok && fe.StatusCode == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err came back as HTTP 401, e.g. an
+// expired or invalid auth token.
+func IsUnauthorized(err error) bool {
+	fe, ok := err.(*Error)
+	return ok && fe.StatusCode == http.StatusUnauthorized
+}
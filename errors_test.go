@@ -0,0 +1,95 @@
+package firebase
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newTestResponse(statusCode int, contentType string, authDebug string) *http.Response {
+	h := http.Header{}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	if authDebug != "" {
+		h.Set(debugHeader, authDebug)
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     h,
+	}
+}
+
+func TestNewErrorParsesJSONBody(t *testing.T) {
+	res := newTestResponse(http.StatusForbidden, "application/json; charset=utf-8", "")
+	e := NewError(res, []byte(`{"error":"Permission denied"}`))
+
+	if e.Message != "Permission denied" {
+		t.Errorf("Message = %q, want %q", e.Message, "Permission denied")
+	}
+	if e.StatusCode != http.StatusForbidden {
+		t.Errorf("StatusCode = %d, want %d", e.StatusCode, http.StatusForbidden)
+	}
+}
+
+func TestNewErrorLeavesMessageEmptyForNonJSONBody(t *testing.T) {
+	res := newTestResponse(http.StatusInternalServerError, "text/html", "")
+	body := []byte("<html>500 oops</html>")
+	e := NewError(res, body)
+
+	if e.Message != "" {
+		t.Errorf("Message = %q, want empty for a non-JSON Content-Type", e.Message)
+	}
+	if string(e.Body) != string(body) {
+		t.Errorf("Body = %q, want %q", e.Body, body)
+	}
+	if e.StatusCode != http.StatusInternalServerError {
+		t.Errorf("StatusCode = %d, want %d", e.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestNewErrorCapturesAuthDebugHeader(t *testing.T) {
+	res := newTestResponse(http.StatusForbidden, "application/json", "Auth token has expired")
+	e := NewError(res, []byte(`{"error":"Permission denied"}`))
+
+	if e.AuthDebug != "Auth token has expired" {
+		t.Errorf("AuthDebug = %q, want %q", e.AuthDebug, "Auth token has expired")
+	}
+}
+
+func TestIsPermissionDenied(t *testing.T) {
+	res := newTestResponse(http.StatusForbidden, "application/json", "")
+	denied := NewError(res, []byte(`{"error":"Permission denied"}`))
+	if !IsPermissionDenied(denied) {
+		t.Errorf("expected a \"Permission denied\" body to be reported as permission denied")
+	}
+
+	other := NewError(res, []byte(`{"error":"something else"}`))
+	if IsPermissionDenied(other) {
+		t.Errorf("a different error message should not be reported as permission denied")
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := NewError(newTestResponse(http.StatusNotFound, "application/json", ""), []byte(`{"error":"not found"}`))
+	if !IsNotFound(notFound) {
+		t.Errorf("expected 404 to be reported as not found")
+	}
+
+	ok := NewError(newTestResponse(http.StatusForbidden, "application/json", ""), []byte(`{"error":"nope"}`))
+	if IsNotFound(ok) {
+		t.Errorf("403 should not be reported as not found")
+	}
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	unauthorized := NewError(newTestResponse(http.StatusUnauthorized, "application/json", ""), []byte(`{"error":"invalid token"}`))
+	if !IsUnauthorized(unauthorized) {
+		t.Errorf("expected 401 to be reported as unauthorized")
+	}
+
+	other := NewError(newTestResponse(http.StatusNotFound, "application/json", ""), []byte(`{"error":"not found"}`))
+	if IsUnauthorized(other) {
+		t.Errorf("404 should not be reported as unauthorized")
+	}
+}
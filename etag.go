@@ -0,0 +1,148 @@
+package firebase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	etagRequestHeader  = "X-Firebase-ETag"
+	ifMatchHeader      = "if-match"
+	etagResponseHeader = "ETag"
+)
+
+// GetWithETag is Get, but also returns the ETag Firebase computed for the
+// value at path, for use with WriteIfMatch, DeleteIfMatch or Transaction.
+func (c *dbClient) GetWithETag(path string, v interface{}) (etag string, err error) {
+	return c.GetWithETagContext(context.Background(), path, v)
+}
+
+// GetWithETagContext is GetWithETag with a caller-supplied context.
+func (c *dbClient) GetWithETagContext(ctx context.Context, path string, v interface{}) (string, error) {
+	req, err := c.buildRequest(ctx, path, GET, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(etagRequestHeader, "true")
+
+	resBody, res, err := c.doRaw(req)
+	if err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal(resBody, v); err != nil {
+		return "", err
+	}
+	return res.Header.Get(etagResponseHeader), nil
+}
+
+// WriteIfMatch writes v to path only if the server's current ETag for path
+// equals etag, i.e. nobody else has written to it since it was read with
+// GetWithETag. A mismatch comes back as a *Error with StatusCode 412
+// (see IsPreconditionFailed).
+func (c *dbClient) WriteIfMatch(path, etag string, v interface{}) error {
+	return c.WriteIfMatchContext(context.Background(), path, etag, v)
+}
+
+// WriteIfMatchContext is WriteIfMatch with a caller-supplied context.
+func (c *dbClient) WriteIfMatchContext(ctx context.Context, path, etag string, v interface{}) error {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.conditionalRequest(ctx, PUT, path, etag, jsonData)
+}
+
+// DeleteIfMatch deletes path only if the server's current ETag equals etag.
+func (c *dbClient) DeleteIfMatch(path, etag string) error {
+	return c.DeleteIfMatchContext(context.Background(), path, etag)
+}
+
+// DeleteIfMatchContext is DeleteIfMatch with a caller-supplied context.
+func (c *dbClient) DeleteIfMatchContext(ctx context.Context, path, etag string) error {
+	return c.conditionalRequest(ctx, DELETE, path, etag, nil)
+}
+
+func (c *dbClient) conditionalRequest(ctx context.Context, method Method, path, etag string, body []byte) error {
+	req, err := c.buildRequest(ctx, path, method, body, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(ifMatchHeader, etag)
+
+	_, _, err = c.doRaw(req)
+	return err
+}
+
+// doRaw executes req directly, bypassing the retry policy: conditional
+// requests and their 412 handling are retried at the Transaction level
+// instead, where a retry means re-reading the ETag, not resending the same
+// stale one.
+func (c *dbClient) doRaw(req *http.Request) ([]byte, *http.Response, error) {
+	res, err := c.doWithDebug(req, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, nil, NewError(res, resBody)
+	}
+
+	return resBody, res, nil
+}
+
+// IsPreconditionFailed reports whether err is a 412 from WriteIfMatch or
+// DeleteIfMatch, i.e. the value changed since it was read with GetWithETag.
+func IsPreconditionFailed(err error) bool {
+	fe, ok := err.(*Error)
+	return ok && fe.StatusCode == http.StatusPreconditionFailed
+}
+
+// defaultTransactionAttempts bounds how many times Transaction re-reads and
+// retries before giving up on a value under heavy write contention.
+const defaultTransactionAttempts = 25
+
+// Transaction performs a compare-and-swap update at path: it reads the
+// current value with its ETag, calls fn to compute the next value, and
+// writes it back conditioned on that ETag. If another writer raced it
+// (412 Precondition Failed), it retries from a fresh read. This gives
+// callers a real compare-and-swap primitive comparable to what the
+// streaming SDKs offer.
+func (c *dbClient) Transaction(path string, fn func(current json.RawMessage) (interface{}, error)) error {
+	return c.TransactionContext(context.Background(), path, fn)
+}
+
+// TransactionContext is Transaction with a caller-supplied context.
+func (c *dbClient) TransactionContext(ctx context.Context, path string, fn func(current json.RawMessage) (interface{}, error)) error {
+	for attempt := 0; attempt < defaultTransactionAttempts; attempt++ {
+		var current json.RawMessage
+		etag, err := c.GetWithETagContext(ctx, path, &current)
+		if err != nil {
+			return err
+		}
+
+		next, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		err = c.WriteIfMatchContext(ctx, path, etag, next)
+		if err == nil {
+			return nil
+		}
+		if !IsPreconditionFailed(err) {
+			return err
+		}
+		// Someone else wrote first; loop around and retry from a fresh read.
+	}
+	return fmt.Errorf("firebase: transaction on %s did not converge after %d attempts", path, defaultTransactionAttempts)
+}
@@ -0,0 +1,75 @@
+package firebase
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTransactionRetriesOnPreconditionFailed(t *testing.T) {
+	var writes int
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		switch req.Method {
+		case "GET":
+			h := http.Header{}
+			h.Set("ETag", "etag-1")
+			return &http.Response{StatusCode: http.StatusOK, Header: h, Body: io.NopCloser(strings.NewReader("1"))}, nil
+		case "PUT":
+			writes++
+			if writes == 1 {
+				return &http.Response{StatusCode: http.StatusPreconditionFailed, Body: io.NopCloser(strings.NewReader(`{"error":"precondition failed"}`))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("2"))}, nil
+		default:
+			t.Fatalf("unexpected method %s", req.Method)
+			return nil, nil
+		}
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+
+	err := c.Transaction("/counter", func(current json.RawMessage) (interface{}, error) {
+		var n int
+		if err := json.Unmarshal(current, &n); err != nil {
+			return nil, err
+		}
+		return n + 1, nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if writes != 2 {
+		t.Errorf("expected the write to be retried once after a 412, got %d writes", writes)
+	}
+}
+
+func TestTransactionGivesUpOnOtherErrors(t *testing.T) {
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(`{"error":"Permission denied"}`))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+
+	err := c.Transaction("/counter", func(current json.RawMessage) (interface{}, error) {
+		t.Fatalf("fn should not be called when the read fails")
+		return nil, nil
+	})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if IsPreconditionFailed(err) {
+		t.Errorf("a 403 should not be reported as a precondition failure")
+	}
+}
+
+func TestIsPreconditionFailed(t *testing.T) {
+	if !IsPreconditionFailed(&Error{StatusCode: http.StatusPreconditionFailed}) {
+		t.Errorf("expected 412 to be reported as a precondition failure")
+	}
+	if IsPreconditionFailed(&Error{StatusCode: http.StatusOK}) {
+		t.Errorf("200 should not be reported as a precondition failure")
+	}
+	if IsPreconditionFailed(nil) {
+		t.Errorf("a nil error should not be reported as a precondition failure")
+	}
+}
@@ -0,0 +1,80 @@
+package firebase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRefBuildsOrderByAndRangeParams(t *testing.T) {
+	var capturedQuery url.Values
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		capturedQuery = req.URL.Query()
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"a":1}`))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+
+	var v map[string]int
+	if err := c.Ref("/users").OrderByChild("age").StartAt(18).EndAt(30).LimitToFirst(5).Get(&v); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	cases := map[string]string{
+		ParamOrderBy:      `"age"`,
+		ParamStartAt:      "18",
+		ParamEndAt:        "30",
+		ParamLimitToFirst: "5",
+	}
+	for param, want := range cases {
+		if got := capturedQuery.Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+}
+
+func TestRefQuotesStringValuesButNotNumbers(t *testing.T) {
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if got := q.Get(ParamEqualTo); got != `"bob"` {
+			t.Errorf("equalTo = %q, want %q (strings must be JSON-quoted)", got, `"bob"`)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+
+	var v map[string]int
+	if err := c.Ref("/users").OrderByChild("name").EqualTo("bob").Get(&v); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+}
+
+func TestRefOrderByKeyAndValue(t *testing.T) {
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if got := q.Get(ParamOrderBy); got != `"$key"` {
+			t.Errorf("orderBy = %q, want %q", got, `"$key"`)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+
+	var v map[string]int
+	if err := c.Ref("/users").OrderByKey().Get(&v); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+}
+
+func TestBuildRequestRejectsOrderByOnMutations(t *testing.T) {
+	c := NewDBClient("https://example.firebaseio.com", "", false, &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("request should not have been sent")
+		return nil, nil
+	}})
+
+	_, err := c.buildRequest(context.Background(), "/users", PUT, nil, url.Values{ParamOrderBy: {`"age"`}})
+	if err == nil {
+		t.Fatalf("expected an error building a PUT request with orderBy set")
+	}
+}
@@ -0,0 +1,70 @@
+package firebase
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures automatic retries for transient failures.
+// InitialBackoff/MaxBackoff/Jitter follow Google's REST API guidance:
+// backoff doubles each attempt up to MaxBackoff, plus uniform jitter in
+// [0, backoff) to avoid synchronized retries across clients.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Jitter         bool
+	//AllowPOSTRetry opts in to retrying POST. POST creates a new push ID in
+	//Firebase, so retrying it by default would duplicate data; only set
+	//this if the caller has its own de-duplication.
+	AllowPOSTRetry bool
+}
+
+// DefaultRetryPolicy is a reasonable policy for transient 5xx/transport
+// failures: 4 attempts, starting at 200ms and capping at 5s, with jitter.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Jitter:         true,
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff * time.Duration(int64(1)<<uint(attempt))
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter && d > 0 {
+		d += time.Duration(rand.Int63n(int64(d)))
+	}
+	return d
+}
+
+// retryableStatus reports whether a response status is worth retrying, per
+// RetryPolicy; these are the transient server-side failures Firebase (and
+// Google's REST APIs generally) recommend retrying.
+func retryableStatus(code int) bool {
+	switch code {
+	case 500, 502, 503, 504:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether method may be retried at all under policy:
+// GET/PUT/DELETE/PATCH are idempotent and always eligible, POST only if the
+// caller opted in via AllowPOSTRetry.
+func (p RetryPolicy) retryable(method Method) bool {
+	if method == POST {
+		return p.AllowPOSTRetry
+	}
+	return true
+}
+
+// SetRetryPolicy enables automatic retries using p. Without a call to
+// SetRetryPolicy, requests are attempted exactly once, matching prior
+// behavior.
+func (c *dbClient) SetRetryPolicy(p RetryPolicy) {
+	c.retryPolicy = &p
+}
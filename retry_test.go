@@ -0,0 +1,112 @@
+package firebase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffCapsAtMaxBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	cases := map[int]time.Duration{
+		0: 100 * time.Millisecond,
+		1: 200 * time.Millisecond,
+		2: 300 * time.Millisecond, // would be 400ms uncapped
+		5: 300 * time.Millisecond,
+	}
+	for attempt, want := range cases {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyRetryableGatesPOST(t *testing.T) {
+	p := RetryPolicy{}
+	if p.retryable(POST) {
+		t.Errorf("POST should not be retryable by default")
+	}
+	for _, m := range []Method{GET, PUT, DELETE, PATCH} {
+		if !p.retryable(m) {
+			t.Errorf("%s should be retryable by default", m)
+		}
+	}
+
+	p.AllowPOSTRetry = true
+	if !p.retryable(POST) {
+		t.Errorf("POST should be retryable once AllowPOSTRetry is set")
+	}
+}
+
+func TestExecuteRequestRetriesRetryableStatus(t *testing.T) {
+	var attempts int
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{"error":"busy"}`))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true}`))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	var v map[string]bool
+	if err := c.Get("/a", &v); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts (2 failures + 1 success), got %d", attempts)
+	}
+}
+
+func TestExecuteRequestDoesNotRetryPOSTByDefault(t *testing.T) {
+	var attempts int
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{"error":"busy"}`))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	if err := c.Create("/a", map[string]int{"x": 1}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected exactly 1 attempt for POST without AllowPOSTRetry, got %d", attempts)
+	}
+}
+
+// TestExecuteRequestHonorsContextDuringBackoff is a regression test for a
+// bug where the retry loop's wait between attempts used time.Sleep and
+// ignored ctx entirely, so a canceled/deadlined context still had to sleep
+// out the full backoff before the request returned.
+func TestExecuteRequestHonorsContextDuringBackoff(t *testing.T) {
+	var attempts int
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{"error":"busy"}`))}, nil
+	}}
+	c := NewDBClient("https://example.firebaseio.com", "", false, client)
+	c.SetRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	var v map[string]bool
+	err := c.GetContext(ctx, "/a", &v)
+	elapsed := time.Since(start)
+
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("GetContext took %s, expected it to return promptly instead of sleeping out the backoff", elapsed)
+	}
+	if attempts != 1 {
+		t.Errorf("expected the first attempt to run before the canceled context was observed, got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,167 @@
+package firebase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EventType identifies the kind of change delivered over a Stream, as
+// defined by the Firebase realtime streaming (Server-Sent Events)
+// protocol.
+type EventType string
+
+const (
+	EventPut         EventType = "put"
+	EventPatch       EventType = "patch"
+	EventKeepAlive   EventType = "keep-alive"
+	EventCancel      EventType = "cancel"
+	EventAuthRevoked EventType = "auth_revoked"
+)
+
+// Event is a single update delivered on the channel passed to Stream.
+type Event struct {
+	Type EventType
+	Path string
+	Data json.RawMessage
+}
+
+// streamPayload mirrors the JSON body Firebase sends on "data: " lines for
+// put/patch events.
+type streamPayload struct {
+	Path string          `json:"path"`
+	Data json.RawMessage `json:"data"`
+}
+
+const (
+	streamInitialBackoff = 1 * time.Second
+	streamMaxBackoff     = 30 * time.Second
+)
+
+// Stream opens a long-lived HTTP GET to path with "Accept:
+// text/event-stream" and delivers every put/patch Firebase sends on events
+// until the returned cancel func is called. This is the primary feature
+// that makes Firebase interesting versus a plain REST database: instead of
+// polling Get, callers learn about changes as they happen.
+//
+// If the connection drops, Stream reconnects automatically with
+// exponential backoff and resumes from the last event it saw via the
+// Last-Event-ID header, so callers do not need to worry about missing
+// updates during a reconnect. A "cancel" or "auth_revoked" event from the
+// server (the security rules no longer allow this read) is terminal: it is
+// delivered once on events and the stream is not retried.
+func (c *dbClient) Stream(path string, events chan<- Event) (cancel func(), err error) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	go c.streamLoop(ctx, path, events)
+
+	return cancelFunc, nil
+}
+
+func (c *dbClient) streamLoop(ctx context.Context, path string, events chan<- Event) {
+	var lastEventID string
+	backoff := streamInitialBackoff
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		connectedAt := time.Now()
+		permanent, err := c.streamOnce(ctx, path, events, &lastEventID)
+		if permanent || ctx.Err() != nil {
+			return
+		}
+
+		// A connection that stayed up for a while was healthy; don't let a
+		// single drop after a long run start us back off at max backoff.
+		if time.Since(connectedAt) > streamMaxBackoff {
+			backoff = streamInitialBackoff
+		}
+		_ = err // connection errors are expected during normal reconnects
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff + time.Duration(rand.Int63n(int64(backoff)+1))):
+		}
+
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+// streamOnce holds a single SSE connection open until it errors or the
+// server sends a terminal event. permanent is true when the caller should
+// not reconnect (the server revoked access).
+func (c *dbClient) streamOnce(ctx context.Context, path string, events chan<- Event, lastEventID *string) (permanent bool, err error) {
+	req, err := c.buildRequest(ctx, path, GET, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastEventID != "" {
+		req.Header.Set("Last-Event-ID", *lastEventID)
+	}
+
+	resp, err := c.doWithDebug(req, false)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("firebase: stream request failed: %s", resp.Status)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var eventType string
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return false, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "id: "):
+			*lastEventID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := strings.TrimPrefix(line, "data: ")
+
+			switch EventType(eventType) {
+			case EventKeepAlive:
+				// no-op, just keeps the connection from looking idle
+			case EventCancel, EventAuthRevoked:
+				select {
+				case events <- Event{Type: EventType(eventType)}:
+				case <-ctx.Done():
+					return true, ctx.Err()
+				}
+				return true, fmt.Errorf("firebase: stream terminated by server: %s", eventType)
+			case EventPut, EventPatch:
+				var payload streamPayload
+				if err := json.Unmarshal([]byte(data), &payload); err != nil {
+					return false, err
+				}
+				select {
+				case events <- Event{Type: EventType(eventType), Path: payload.Path, Data: payload.Data}:
+				case <-ctx.Done():
+					return false, ctx.Err()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package firebase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamOnceParsesPutAndPatchEvents(t *testing.T) {
+	body := "id: 1\n" +
+		"event: put\n" +
+		"data: {\"path\":\"/a\",\"data\":1}\n\n" +
+		"event: keep-alive\n" +
+		"data: null\n\n" +
+		"id: 2\n" +
+		"event: patch\n" +
+		"data: {\"path\":\"/b\",\"data\":{\"x\":2}}\n\n"
+
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}}
+	c := &dbClient{baseUrl: "https://example.firebaseio.com", client: client}
+	events := make(chan Event, 2)
+	var lastEventID string
+
+	permanent, err := c.streamOnce(context.Background(), "/a", events, &lastEventID)
+	if permanent {
+		t.Fatalf("expected a non-permanent error at end of stream")
+	}
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+	close(events)
+
+	var got []Event
+	for e := range events {
+		got = append(got, e)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events (keep-alive should not be delivered), got %d: %+v", len(got), got)
+	}
+	if got[0].Type != EventPut || got[0].Path != "/a" || string(got[0].Data) != "1" {
+		t.Errorf("unexpected first event: %+v", got[0])
+	}
+	if got[1].Type != EventPatch || got[1].Path != "/b" || string(got[1].Data) != `{"x":2}` {
+		t.Errorf("unexpected second event: %+v", got[1])
+	}
+	if lastEventID != "2" {
+		t.Errorf("lastEventID = %q, want %q", lastEventID, "2")
+	}
+}
+
+func TestStreamOnceTerminatesOnAuthRevoked(t *testing.T) {
+	body := "event: auth_revoked\ndata: null\n\n"
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}}
+	c := &dbClient{baseUrl: "https://example.firebaseio.com", client: client}
+	events := make(chan Event, 1)
+	var lastEventID string
+
+	permanent, err := c.streamOnce(context.Background(), "/a", events, &lastEventID)
+	if !permanent {
+		t.Fatalf("expected permanent=true for auth_revoked")
+	}
+	if err == nil {
+		t.Fatalf("expected an error describing the server-side termination")
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != EventAuthRevoked {
+			t.Errorf("expected EventAuthRevoked, got %v", e.Type)
+		}
+	default:
+		t.Fatalf("expected the terminal event to be delivered before returning")
+	}
+}
+
+func TestStreamOnceStopsOnContextCancel(t *testing.T) {
+	// An events send that nobody drains must not hang forever once ctx is
+	// canceled.
+	body := "event: put\ndata: {\"path\":\"/a\",\"data\":1}\n\n"
+	client := &fakeClient{do: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}}
+	c := &dbClient{baseUrl: "https://example.firebaseio.com", client: client}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	events := make(chan Event) // unbuffered and never drained
+	done := make(chan struct{})
+	go func() {
+		c.streamOnce(ctx, "/a", events, new(string))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("streamOnce blocked on a stalled events send past context cancellation")
+	}
+}